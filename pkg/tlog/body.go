@@ -0,0 +1,221 @@
+package tlog
+
+import (
+	"crypto"
+	_ "crypto/sha512" // register crypto.SHA512 for (crypto.Hash).New, used by decodeHexDigest
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	rekorModels "github.com/sigstore/rekor/pkg/generated/models"
+)
+
+// EntryBody is the parsed, type-specific content of a transparency log
+// entry's canonicalized body. It exposes just enough of the body to bind the
+// entry to the artifact and key material it attests to, regardless of which
+// concrete Rekor entry type produced it.
+type EntryBody interface {
+	// ArtifactDigest returns the digest algorithm and raw digest bytes the
+	// entry attests to.
+	ArtifactDigest() (crypto.Hash, []byte, error)
+
+	// PublicKeyOrCertificate returns the PEM-encoded public key or
+	// certificate embedded in the entry.
+	PublicKeyOrCertificate() ([]byte, error)
+}
+
+// ParseEntryBody decodes body (the base64 canonicalized body of a tlog
+// entry) and returns a typed EntryBody for its kind/apiVersion. Supported
+// kinds are hashedrekord v0.0.1, intoto v0.0.1/v0.0.2, and rekord v0.0.1.
+func ParseEntryBody(body string) (EntryBody, error) {
+	decoded, err := base64.StdEncoding.DecodeString(body)
+	if err != nil {
+		return nil, fmt.Errorf("decoding tlog entry body: %w", err)
+	}
+
+	var kind struct {
+		Kind       string `json:"kind"`
+		APIVersion string `json:"apiVersion"`
+	}
+	if err := json.Unmarshal(decoded, &kind); err != nil {
+		return nil, fmt.Errorf("decoding tlog entry kind: %w", err)
+	}
+
+	switch kind.Kind {
+	case "hashedrekord":
+		if kind.APIVersion != "0.0.1" {
+			return nil, fmt.Errorf("unsupported hashedrekord apiVersion %q", kind.APIVersion)
+		}
+		var entry rekorModels.Hashedrekord
+		if err := json.Unmarshal(decoded, &entry); err != nil {
+			return nil, fmt.Errorf("decoding hashedrekord entry: %w", err)
+		}
+		spec, ok := entry.Spec.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("decoding hashedrekord spec: unexpected type %T", entry.Spec)
+		}
+		var schema rekorModels.HashedrekordV001Schema
+		if err := remarshal(spec, &schema); err != nil {
+			return nil, fmt.Errorf("decoding hashedrekord spec: %w", err)
+		}
+		return &hashedrekordBody{schema}, nil
+	case "intoto":
+		switch kind.APIVersion {
+		case "0.0.1":
+			var entry rekorModels.Intoto
+			if err := json.Unmarshal(decoded, &entry); err != nil {
+				return nil, fmt.Errorf("decoding intoto entry: %w", err)
+			}
+			spec, ok := entry.Spec.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("decoding intoto spec: unexpected type %T", entry.Spec)
+			}
+			var schema rekorModels.IntotoV001Schema
+			if err := remarshal(spec, &schema); err != nil {
+				return nil, fmt.Errorf("decoding intoto spec: %w", err)
+			}
+			return &intotoV001Body{schema}, nil
+		case "0.0.2":
+			var entry rekorModels.Intoto
+			if err := json.Unmarshal(decoded, &entry); err != nil {
+				return nil, fmt.Errorf("decoding intoto entry: %w", err)
+			}
+			spec, ok := entry.Spec.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("decoding intoto spec: unexpected type %T", entry.Spec)
+			}
+			var schema rekorModels.IntotoV002Schema
+			if err := remarshal(spec, &schema); err != nil {
+				return nil, fmt.Errorf("decoding intoto spec: %w", err)
+			}
+			return &intotoV002Body{schema}, nil
+		default:
+			return nil, fmt.Errorf("unsupported intoto apiVersion %q", kind.APIVersion)
+		}
+	case "rekord":
+		if kind.APIVersion != "0.0.1" {
+			return nil, fmt.Errorf("unsupported rekord apiVersion %q", kind.APIVersion)
+		}
+		var entry rekorModels.Rekord
+		if err := json.Unmarshal(decoded, &entry); err != nil {
+			return nil, fmt.Errorf("decoding rekord entry: %w", err)
+		}
+		spec, ok := entry.Spec.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("decoding rekord spec: unexpected type %T", entry.Spec)
+		}
+		var schema rekorModels.RekordV001Schema
+		if err := remarshal(spec, &schema); err != nil {
+			return nil, fmt.Errorf("decoding rekord spec: %w", err)
+		}
+		return &rekordBody{schema}, nil
+	default:
+		return nil, fmt.Errorf("unsupported tlog entry kind %q", kind.Kind)
+	}
+}
+
+// remarshal round-trips v through JSON into out, used to turn the untyped
+// Spec field of the outer entry envelope into its concrete schema type.
+func remarshal(v interface{}, out interface{}) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, out)
+}
+
+func decodeHexDigest(algorithm, value *string) (crypto.Hash, []byte, error) {
+	if algorithm == nil || value == nil {
+		return 0, nil, fmt.Errorf("entry is missing a digest")
+	}
+
+	var hash crypto.Hash
+	switch *algorithm {
+	case "sha256":
+		hash = crypto.SHA256
+	case "sha512":
+		hash = crypto.SHA512
+	default:
+		return 0, nil, fmt.Errorf("unsupported digest algorithm %q", *algorithm)
+	}
+
+	digest, err := hex.DecodeString(*value)
+	if err != nil {
+		return 0, nil, fmt.Errorf("decoding digest: %w", err)
+	}
+
+	return hash, digest, nil
+}
+
+type hashedrekordBody struct {
+	schema rekorModels.HashedrekordV001Schema
+}
+
+func (b *hashedrekordBody) ArtifactDigest() (crypto.Hash, []byte, error) {
+	if b.schema.Data == nil || b.schema.Data.Hash == nil {
+		return 0, nil, fmt.Errorf("hashedrekord entry is missing a digest")
+	}
+	return decodeHexDigest(b.schema.Data.Hash.Algorithm, b.schema.Data.Hash.Value)
+}
+
+func (b *hashedrekordBody) PublicKeyOrCertificate() ([]byte, error) {
+	if b.schema.Signature == nil || b.schema.Signature.PublicKey == nil {
+		return nil, fmt.Errorf("hashedrekord entry is missing a public key")
+	}
+	return b.schema.Signature.PublicKey.Content, nil
+}
+
+type rekordBody struct {
+	schema rekorModels.RekordV001Schema
+}
+
+func (b *rekordBody) ArtifactDigest() (crypto.Hash, []byte, error) {
+	if b.schema.Data == nil || b.schema.Data.Hash == nil {
+		return 0, nil, fmt.Errorf("rekord entry is missing a digest")
+	}
+	return decodeHexDigest(b.schema.Data.Hash.Algorithm, b.schema.Data.Hash.Value)
+}
+
+func (b *rekordBody) PublicKeyOrCertificate() ([]byte, error) {
+	if b.schema.Signature == nil || b.schema.Signature.PublicKey == nil {
+		return nil, fmt.Errorf("rekord entry is missing a public key")
+	}
+	return b.schema.Signature.PublicKey.Content, nil
+}
+
+type intotoV001Body struct {
+	schema rekorModels.IntotoV001Schema
+}
+
+func (b *intotoV001Body) ArtifactDigest() (crypto.Hash, []byte, error) {
+	if b.schema.Content == nil || b.schema.Content.Hash == nil {
+		return 0, nil, fmt.Errorf("intoto entry is missing a digest")
+	}
+	return decodeHexDigest(b.schema.Content.Hash.Algorithm, b.schema.Content.Hash.Value)
+}
+
+func (b *intotoV001Body) PublicKeyOrCertificate() ([]byte, error) {
+	if b.schema.PublicKey == nil {
+		return nil, fmt.Errorf("intoto entry is missing a public key")
+	}
+	return []byte(*b.schema.PublicKey), nil
+}
+
+type intotoV002Body struct {
+	schema rekorModels.IntotoV002Schema
+}
+
+func (b *intotoV002Body) ArtifactDigest() (crypto.Hash, []byte, error) {
+	if b.schema.Content == nil || b.schema.Content.Hash == nil {
+		return 0, nil, fmt.Errorf("intoto entry is missing a digest")
+	}
+	return decodeHexDigest(b.schema.Content.Hash.Algorithm, b.schema.Content.Hash.Value)
+}
+
+func (b *intotoV002Body) PublicKeyOrCertificate() ([]byte, error) {
+	if len(b.schema.PublicKey) == 0 {
+		return nil, fmt.Errorf("intoto entry is missing a public key")
+	}
+	return []byte(b.schema.PublicKey[0]), nil
+}
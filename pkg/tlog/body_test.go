@@ -0,0 +1,52 @@
+package tlog
+
+import (
+	"crypto"
+	"encoding/hex"
+	"testing"
+)
+
+func TestDecodeHexDigest(t *testing.T) {
+	value := hex.EncodeToString([]byte("digest bytes"))
+
+	t.Run("sha256", func(t *testing.T) {
+		algorithm := "sha256"
+		hash, digest, err := decodeHexDigest(&algorithm, &value)
+		if err != nil {
+			t.Fatalf("decodeHexDigest() error = %v", err)
+		}
+		if hash != crypto.SHA256 {
+			t.Fatalf("decodeHexDigest() hash = %v, want SHA256", hash)
+		}
+		// Must not panic: crypto.SHA256 is registered by crypto/sha256.
+		hash.New().Write(digest)
+	})
+
+	t.Run("sha512", func(t *testing.T) {
+		algorithm := "sha512"
+		hash, digest, err := decodeHexDigest(&algorithm, &value)
+		if err != nil {
+			t.Fatalf("decodeHexDigest() error = %v", err)
+		}
+		if hash != crypto.SHA512 {
+			t.Fatalf("decodeHexDigest() hash = %v, want SHA512", hash)
+		}
+		// Regression guard: crypto.SHA512 must be registered (by importing
+		// crypto/sha512 for its side effect), or this panics instead of
+		// erroring on a legitimate sha512 digest.
+		hash.New().Write(digest)
+	})
+
+	t.Run("unsupported algorithm", func(t *testing.T) {
+		algorithm := "md5"
+		if _, _, err := decodeHexDigest(&algorithm, &value); err == nil {
+			t.Fatal("decodeHexDigest() error = nil, want error for unsupported algorithm")
+		}
+	})
+
+	t.Run("nil inputs", func(t *testing.T) {
+		if _, _, err := decodeHexDigest(nil, &value); err == nil {
+			t.Fatal("decodeHexDigest() error = nil, want error for nil algorithm")
+		}
+	})
+}
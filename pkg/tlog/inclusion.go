@@ -0,0 +1,253 @@
+package tlog
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/github/sigstore-verifier/pkg/root"
+)
+
+// InclusionProof is a transparency log's proof that an entry at LogIndex is
+// included in the Merkle tree of size TreeSize committed to by Checkpoint,
+// a signed note whose body records RootHash.
+type InclusionProof struct {
+	LogIndex   int64
+	TreeSize   int64
+	RootHash   string
+	Hashes     []string
+	Checkpoint string
+}
+
+// VerifyInclusion verifies that entry's InclusionProof recomputes to a root
+// hash matching a checkpoint signed by the entry's tlog, per RFC 6962. This
+// is a true offline check: unlike VerifySET, it does not merely trust a
+// signed timestamp, it proves the entry is actually included in the tree
+// the checkpoint commits to. Fails closed if entry carries no inclusion
+// proof.
+func VerifyInclusion(entry Entry, tlogVerifiers map[string]*root.TlogVerifier) error {
+	proof := entry.InclusionProof()
+	if proof == nil {
+		return errors.New("tlog entry has no inclusion proof available for offline verification")
+	}
+
+	keyID := entry.LogKeyID()
+	if keyID == nil {
+		return errors.New("tlog entry has no log key ID")
+	}
+	hexKeyID := hex.EncodeToString([]byte(*keyID))
+	tlogVerifier, ok := tlogVerifiers[hexKeyID]
+	if !ok {
+		return fmt.Errorf("unable to find tlog information for key %s", hexKeyID)
+	}
+
+	body, err := base64.StdEncoding.DecodeString(entry.Body())
+	if err != nil {
+		return fmt.Errorf("decoding tlog entry body: %w", err)
+	}
+
+	rootHash, err := hex.DecodeString(proof.RootHash)
+	if err != nil {
+		return fmt.Errorf("decoding inclusion proof root hash: %w", err)
+	}
+
+	hashes := make([][]byte, len(proof.Hashes))
+	for i, h := range proof.Hashes {
+		decoded, err := hex.DecodeString(h)
+		if err != nil {
+			return fmt.Errorf("decoding inclusion proof hash %d: %w", i, err)
+		}
+		hashes[i] = decoded
+	}
+
+	computedRoot, err := rootFromInclusionProof(leafHash(body), proof.LogIndex, proof.TreeSize, hashes)
+	if err != nil {
+		return fmt.Errorf("recomputing merkle root: %w", err)
+	}
+
+	if !bytes.Equal(computedRoot, rootHash) {
+		return errors.New("computed inclusion proof root does not match the log entry's root hash")
+	}
+
+	if err := verifyCheckpoint(proof.Checkpoint, rootHash, proof.TreeSize, tlogVerifier); err != nil {
+		return fmt.Errorf("verifying checkpoint: %w", err)
+	}
+
+	return nil
+}
+
+// leafHash hashes the leaf per RFC 6962 section 2.1: H(0x00 || data).
+func leafHash(data []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{0x00})
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// hashChildren combines two nodes per RFC 6962 section 2.1: H(0x01 || l || r).
+func hashChildren(l, r []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{0x01})
+	h.Write(l)
+	h.Write(r)
+	return h.Sum(nil)
+}
+
+// rootFromInclusionProof recomputes the Merkle tree root from a leaf hash,
+// its index, the tree size, and the audit path, following RFC 6962 section
+// 2.1.1's recursive MTH/PATH definitions directly: PATH(m, D[n]) splits the
+// tree at k, the largest power of two less than n, recurses into whichever
+// side contains leaf m, and appends the *other* side's subtree hash to the
+// end of the path. So proof is ordered bottom-up (proof[0] is the sibling
+// closest to the leaf, proof[len-1] the one closest to the root), and is
+// consumed from the end as the recursion descends from the root toward the
+// leaf.
+func rootFromInclusionProof(leaf []byte, index, treeSize int64, proof [][]byte) ([]byte, error) {
+	if index < 0 || treeSize <= 0 || index >= treeSize {
+		return nil, fmt.Errorf("invalid leaf index %d for tree size %d", index, treeSize)
+	}
+
+	hash, remaining, err := subtreeRootFromProof(leaf, index, treeSize, proof)
+	if err != nil {
+		return nil, err
+	}
+	if len(remaining) != 0 {
+		return nil, fmt.Errorf("inclusion proof has %d unconsumed hashes", len(remaining))
+	}
+
+	return hash, nil
+}
+
+// subtreeRootFromProof computes the root of the treeSize-leaf subtree
+// containing leaf at index, consuming as many trailing elements of proof as
+// that subtree's levels require, and returns whatever proof is left over.
+// Each level takes its own sibling before recursing, so anything still left
+// once the whole tree is computed is surplus.
+func subtreeRootFromProof(leaf []byte, index, treeSize int64, proof [][]byte) ([]byte, [][]byte, error) {
+	if treeSize == 1 {
+		return leaf, proof, nil
+	}
+
+	if len(proof) == 0 {
+		return nil, nil, errors.New("inclusion proof is too short for the given tree size")
+	}
+	sibling := proof[len(proof)-1]
+	proof = proof[:len(proof)-1]
+
+	k := largestPowerOfTwoBelow(treeSize)
+	if index < k {
+		left, remaining, err := subtreeRootFromProof(leaf, index, k, proof)
+		if err != nil {
+			return nil, nil, err
+		}
+		return hashChildren(left, sibling), remaining, nil
+	}
+
+	right, remaining, err := subtreeRootFromProof(leaf, index-k, treeSize-k, proof)
+	if err != nil {
+		return nil, nil, err
+	}
+	return hashChildren(sibling, right), remaining, nil
+}
+
+// largestPowerOfTwoBelow returns the largest power of two strictly less
+// than n, for n > 1.
+func largestPowerOfTwoBelow(n int64) int64 {
+	k := int64(1)
+	for k*2 < n {
+		k *= 2
+	}
+	return k
+}
+
+// verifyCheckpoint parses a signed checkpoint (a signed note: a body of
+// "origin\nsize\nrootHash(base64)\n", a blank line, then one or more
+// "— name signature" lines) and verifies both that it commits to rootHash
+// and treeSize, and that one of its signatures verifies against
+// tlogVerifier's public key.
+func verifyCheckpoint(checkpoint string, rootHash []byte, treeSize int64, tlogVerifier *root.TlogVerifier) error {
+	body, sigLines, ok := splitCheckpoint(checkpoint)
+	if !ok {
+		return errors.New("malformed checkpoint: missing signature block")
+	}
+
+	lines := strings.Split(strings.TrimSuffix(body, "\n"), "\n")
+	if len(lines) != 3 {
+		return fmt.Errorf("malformed checkpoint body: expected 3 lines, got %d", len(lines))
+	}
+
+	checkpointSize, err := parseCheckpointSize(lines[1])
+	if err != nil {
+		return err
+	}
+	if checkpointSize != treeSize {
+		return fmt.Errorf("checkpoint tree size %d does not match inclusion proof tree size %d", checkpointSize, treeSize)
+	}
+
+	checkpointHash, err := base64.StdEncoding.DecodeString(lines[2])
+	if err != nil {
+		return fmt.Errorf("decoding checkpoint root hash: %w", err)
+	}
+	if !bytes.Equal(checkpointHash, rootHash) {
+		return errors.New("checkpoint root hash does not match inclusion proof root hash")
+	}
+
+	for _, sigLine := range sigLines {
+		sig, err := decodeCheckpointSignature(sigLine)
+		if err != nil {
+			continue
+		}
+		if err := tlogVerifier.Verifier.VerifySignature(bytes.NewReader(sig), strings.NewReader(body)); err == nil {
+			return nil
+		}
+	}
+
+	return errors.New("no checkpoint signature verified against the tlog's public key")
+}
+
+// splitCheckpoint separates a signed note's body from its "— name sig"
+// lines, which follow a blank line.
+func splitCheckpoint(checkpoint string) (body string, sigLines []string, ok bool) {
+	idx := strings.Index(checkpoint, "\n\n")
+	if idx < 0 {
+		return "", nil, false
+	}
+	body = checkpoint[:idx+1]
+	for _, line := range strings.Split(strings.TrimSpace(checkpoint[idx+2:]), "\n") {
+		if line != "" {
+			sigLines = append(sigLines, line)
+		}
+	}
+	return body, sigLines, len(sigLines) > 0
+}
+
+func parseCheckpointSize(line string) (int64, error) {
+	var size int64
+	if _, err := fmt.Sscanf(line, "%d", &size); err != nil {
+		return 0, fmt.Errorf("parsing checkpoint size: %w", err)
+	}
+	return size, nil
+}
+
+// decodeCheckpointSignature pulls the base64 signature out of a "— name
+// sig" note signature line, dropping the leading 4-byte key hint.
+func decodeCheckpointSignature(line string) ([]byte, error) {
+	parts := strings.Fields(strings.TrimPrefix(line, "— "))
+	if len(parts) != 2 {
+		return nil, errors.New("malformed checkpoint signature line")
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("decoding checkpoint signature: %w", err)
+	}
+	if len(decoded) <= 4 {
+		return nil, errors.New("checkpoint signature too short")
+	}
+
+	return decoded[4:], nil
+}
@@ -0,0 +1,90 @@
+package tlog
+
+import (
+	"fmt"
+	"testing"
+)
+
+// referenceMTH and referencePath are a direct, separately-written
+// transcription of RFC 6962 section 2.1's MTH and 2.1.1's PATH
+// definitions, used as an independent oracle for rootFromInclusionProof.
+// They share only leafHash, hashChildren and largestPowerOfTwoBelow with
+// subtreeRootFromProof, not its proof-consuming recursion.
+
+func referenceMTH(leaves [][]byte) []byte {
+	n := int64(len(leaves))
+	if n == 1 {
+		return leafHash(leaves[0])
+	}
+	k := largestPowerOfTwoBelow(n)
+	return hashChildren(referenceMTH(leaves[:k]), referenceMTH(leaves[k:]))
+}
+
+func referencePath(m int64, leaves [][]byte) [][]byte {
+	n := int64(len(leaves))
+	if n == 1 {
+		return nil
+	}
+	k := largestPowerOfTwoBelow(n)
+	if m < k {
+		return append(referencePath(m, leaves[:k]), referenceMTH(leaves[k:]))
+	}
+	return append(referencePath(m-k, leaves[k:]), referenceMTH(leaves[:k]))
+}
+
+func leavesOfSize(n int64) [][]byte {
+	leaves := make([][]byte, n)
+	for i := range leaves {
+		leaves[i] = []byte(fmt.Sprintf("leaf-%d", i))
+	}
+	return leaves
+}
+
+func TestRootFromInclusionProof(t *testing.T) {
+	for treeSize := int64(1); treeSize <= 80; treeSize++ {
+		leaves := leavesOfSize(treeSize)
+		wantRoot := referenceMTH(leaves)
+
+		for index := int64(0); index < treeSize; index++ {
+			t.Run(fmt.Sprintf("size=%d/index=%d", treeSize, index), func(t *testing.T) {
+				proof := referencePath(index, leaves)
+
+				gotRoot, err := rootFromInclusionProof(leafHash(leaves[index]), index, treeSize, proof)
+				if err != nil {
+					t.Fatalf("rootFromInclusionProof() error = %v", err)
+				}
+				if string(gotRoot) != string(wantRoot) {
+					t.Fatalf("rootFromInclusionProof() = %x, want %x", gotRoot, wantRoot)
+				}
+			})
+		}
+	}
+}
+
+func TestRootFromInclusionProofRejectsTruncatedProof(t *testing.T) {
+	leaves := leavesOfSize(8)
+	proof := referencePath(5, leaves)
+
+	_, err := rootFromInclusionProof(leafHash(leaves[5]), 5, 8, proof[:len(proof)-1])
+	if err == nil {
+		t.Fatal("expected an error for a truncated proof, got nil")
+	}
+}
+
+func TestRootFromInclusionProofRejectsInvalidIndex(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		index     int64
+		treeSize  int64
+	}{
+		{"negative index", -1, 8},
+		{"index equals tree size", 8, 8},
+		{"zero tree size", 0, 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := rootFromInclusionProof(leafHash([]byte("x")), tc.index, tc.treeSize, nil); err == nil {
+				t.Fatal("expected an error, got nil")
+			}
+		})
+	}
+}
@@ -5,11 +5,14 @@ import (
 	"context"
 	"crypto"
 	"crypto/x509"
-	"encoding/hex"
+	"encoding/base64"
 	"encoding/pem"
 	"errors"
 	"fmt"
+	"io"
+	"time"
 
+	"github.com/go-openapi/runtime"
 	rekorClient "github.com/sigstore/rekor/pkg/client"
 	rekorGeneratedClient "github.com/sigstore/rekor/pkg/generated/client"
 	rekorEntries "github.com/sigstore/rekor/pkg/generated/client/entries"
@@ -22,13 +25,22 @@ import (
 )
 
 type ArtifactTransparencyLogVerifier struct {
-	trustedRoot   root.TrustedRoot
-	threshold     int
-	online        bool
-	tlogVerifiers map[string]*root.TlogVerifier
+	trustedRoot               root.TrustedRoot
+	threshold                 int
+	online                    bool
+	tlogVerifiers             map[string]*root.TlogVerifier
+	recoverCertificateFromLog bool
 }
 
-func (p *ArtifactTransparencyLogVerifier) Verify(entity SignedEntity) error {
+// VerifyOptions carries optional inputs to ArtifactTransparencyLogVerifier.Verify
+// that are not derivable from the SignedEntity alone.
+type VerifyOptions struct {
+	// Artifact, if set, is hashed and compared against the digest embedded
+	// in each tlog entry's body. Verify reads it fully and closes nothing.
+	Artifact io.Reader
+}
+
+func (p *ArtifactTransparencyLogVerifier) Verify(entity SignedEntity, opts VerifyOptions) error {
 	entries, err := entity.TlogEntries()
 	if err != nil {
 		return err
@@ -49,57 +61,77 @@ func (p *ArtifactTransparencyLogVerifier) Verify(entity SignedEntity) error {
 		return err
 	}
 
+	var artifact []byte
+	if opts.Artifact != nil {
+		artifact, err = io.ReadAll(opts.Artifact)
+		if err != nil {
+			return fmt.Errorf("reading artifact: %w", err)
+		}
+	}
+
+	if p.recoverCertificateFromLog && verificationContent.Certificate() == nil {
+		verificationContent, err = recoverCertificateFromLog(entries, p.trustedRoot)
+		if err != nil {
+			return err
+		}
+	}
+
 	for _, entry := range entries {
 		err := tlog.ValidateEntry(entry)
 		if err != nil {
 			return err
 		}
 
+		integratedTime := entry.IntegratedTime()
+
 		if !p.online {
 			err = tlog.VerifySET(entry, p.trustedRoot.TlogVerifiers())
 			if err != nil {
 				return err
 			}
-		} else {
-			keyID := entry.LogKeyID()
-			hex64Key := hex.EncodeToString([]byte(*keyID))
-			tlogVerifier, ok := p.tlogVerifiers[hex64Key]
-			if !ok {
-				return fmt.Errorf("unable to find tlog information for key %s", hex64Key)
-			}
 
-			client, verifier, err := getRekorClient(tlogVerifier.BaseURL)
+			// VerifySET only proves the log promised to include the entry by
+			// a given time; it does not prove the entry actually made it
+			// into the tree. Recompute the Merkle inclusion proof against a
+			// signed checkpoint for a true air-gapped verification.
+			if err := tlog.VerifyInclusion(entry, p.trustedRoot.TlogVerifiers()); err != nil {
+				return fmt.Errorf("verifying inclusion proof: %w", err)
+			}
+		} else {
+			proposedEntry, err := proposedEntryFromRekorEntry(entry)
 			if err != nil {
 				return err
 			}
 
-			logIndex := entry.LogIndex()
+			// A sharded Rekor instance may have written this entry to a now
+			// frozen shard whose log index is not addressable via the active
+			// shard's index search, so search every configured shard by the
+			// entry's own content instead of by log index. One flaky or
+			// unreachable shard must not fail verification when another
+			// shard can still confirm the entry, so a shard's failure is
+			// recorded and searching continues; we only give up once no
+			// shard has returned a match.
+			var matches []shardMatch
+			var shardErrs []error
+			for _, tlogVerifier := range p.tlogVerifiers {
+				match, err := searchShardForEntry(tlogVerifier, proposedEntry)
+				if err != nil {
+					shardErrs = append(shardErrs, fmt.Errorf("%s: %w", tlogVerifier.BaseURL, err))
+					continue
+				}
+				matches = append(matches, match...)
+			}
 
-			searchParams := rekorEntries.NewSearchLogQueryParams()
-			searchLogQuery := rekorModels.SearchLogQuery{}
-			searchLogQuery.LogIndexes = []*int64{logIndex}
-			searchParams.SetEntry(&searchLogQuery)
+			if len(matches) == 0 {
+				return fmt.Errorf("unable to locate log entry in any configured tlog shard: %w", errors.Join(shardErrs...))
+			}
 
-			resp, err := client.Entries.SearchLogQuery(searchParams)
+			earliest, err := resolveEarliestMatch(matches)
 			if err != nil {
 				return err
 			}
 
-			if len(resp.Payload) == 0 {
-				return fmt.Errorf("unable to locate log entry %d", *logIndex)
-			} else if len(resp.Payload) > 1 {
-				return errors.New("too many log entries returned")
-			}
-
-			logEntry := resp.Payload[0]
-
-			for _, v := range logEntry {
-				v := v
-				err = rekorVerify.VerifyLogEntry(context.TODO(), &v, *verifier)
-				if err != nil {
-					return err
-				}
-			}
+			integratedTime = time.Unix(*earliest.IntegratedTime, 0)
 		}
 
 		// Ensure entry signature matches signature from bundle
@@ -112,10 +144,37 @@ func (p *ArtifactTransparencyLogVerifier) Verify(entity SignedEntity) error {
 			return errors.New("transparency log certificate does not match")
 		}
 
-		// TODO: if you have access to artifact, check that it matches body subject
+		// Ensure the entry body's subject (digest and key material) is bound
+		// to the artifact and bundle being verified, not a substituted one.
+		body, err := tlog.ParseEntryBody(entry.Body())
+		if err != nil {
+			return err
+		}
+
+		keyOrCert, err := body.PublicKeyOrCertificate()
+		if err != nil {
+			return err
+		}
+		if !verificationContent.CompareKey(derFromPEMOrRaw(keyOrCert)) {
+			return errors.New("transparency log entry body key does not match bundle verification content")
+		}
+
+		if artifact != nil {
+			hash, digest, err := body.ArtifactDigest()
+			if err != nil {
+				return err
+			}
+
+			h := hash.New()
+			h.Write(artifact)
+			if !bytes.Equal(h.Sum(nil), digest) {
+				return errors.New("artifact does not match digest in transparency log entry")
+			}
+		}
 
-		// Check tlog entry time against bundle certificates
-		if !verificationContent.ValidAtTime(entry.IntegratedTime()) {
+		// Check tlog entry time (the earliest integration across shards when
+		// verified online) against bundle certificates
+		if !verificationContent.ValidAtTime(integratedTime) {
 			return errors.New("Integrated time outside certificate validity")
 		}
 	}
@@ -123,13 +182,227 @@ func (p *ArtifactTransparencyLogVerifier) Verify(entity SignedEntity) error {
 	return nil
 }
 
-func NewArtifactTransparencyLogVerifier(trustedRoot root.TrustedRoot, threshold int, online bool, tlogVerifiers map[string]*root.TlogVerifier) *ArtifactTransparencyLogVerifier {
+func NewArtifactTransparencyLogVerifier(trustedRoot root.TrustedRoot, threshold int, online bool, tlogVerifiers map[string]*root.TlogVerifier, recoverCertificateFromLog bool) *ArtifactTransparencyLogVerifier {
 	return &ArtifactTransparencyLogVerifier{
-		trustedRoot:   trustedRoot,
-		threshold:     threshold,
-		online:        online,
-		tlogVerifiers: tlogVerifiers,
+		trustedRoot:               trustedRoot,
+		threshold:                 threshold,
+		online:                    online,
+		tlogVerifiers:             tlogVerifiers,
+		recoverCertificateFromLog: recoverCertificateFromLog,
+	}
+}
+
+// derFromPEMOrRaw returns key's DER encoding. Tlog entry bodies carry
+// PEM-encoded keys/certificates (per the Rekor schemas), but VerificationContent.CompareKey
+// implementations parse DER directly, so callers comparing a body's key
+// material must normalize it first. Inputs that are not PEM are assumed to
+// already be DER and are returned unchanged.
+func derFromPEMOrRaw(key []byte) []byte {
+	if block, _ := pem.Decode(key); block != nil {
+		return block.Bytes
 	}
+	return key
+}
+
+// recoveredVerificationContent adapts an x509 certificate recovered from a
+// tlog entry body to the VerificationContent interface, for bundles that
+// only carry a bare public key.
+type recoveredVerificationContent struct {
+	cert *x509.Certificate
+}
+
+func (r *recoveredVerificationContent) Certificate() *x509.Certificate {
+	return r.cert
+}
+
+func (r *recoveredVerificationContent) CompareKey(key []byte) bool {
+	if other, err := x509.ParseCertificate(key); err == nil {
+		return other.Equal(r.cert)
+	}
+
+	otherPub, err := x509.ParsePKIXPublicKey(key)
+	if err != nil {
+		return false
+	}
+
+	rawOtherPub, err := x509.MarshalPKIXPublicKey(otherPub)
+	if err != nil {
+		return false
+	}
+
+	rawPub, err := x509.MarshalPKIXPublicKey(r.cert.PublicKey)
+	if err != nil {
+		return false
+	}
+
+	return bytes.Equal(rawPub, rawOtherPub)
+}
+
+func (r *recoveredVerificationContent) ValidAtTime(t time.Time) bool {
+	return !t.Before(r.cert.NotBefore) && !t.After(r.cert.NotAfter)
+}
+
+// recoverCertificateFromLog extracts the x509 certificate embedded in a tlog
+// entry body (as the `publicKey` field of hashedrekord/intoto/rekord
+// entries), validates it against the Fulcio certificate authorities in
+// trustedRoot, and returns it as a VerificationContent usable for the rest
+// of the verification pipeline.
+func recoverCertificateFromLog(entries []tlog.Entry, trustedRoot root.TrustedRoot) (VerificationContent, error) {
+	var lastErr error
+	for _, entry := range entries {
+		body, err := tlog.ParseEntryBody(entry.Body())
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		keyOrCert, err := body.PublicKeyOrCertificate()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		block, _ := pem.Decode(keyOrCert)
+		if block == nil {
+			lastErr = errors.New("tlog entry public key is not PEM encoded")
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			lastErr = fmt.Errorf("tlog entry does not contain a certificate: %w", err)
+			continue
+		}
+
+		if err := verifyCertificateAgainstFulcio(cert, trustedRoot, entry.IntegratedTime()); err != nil {
+			lastErr = err
+			continue
+		}
+
+		return &recoveredVerificationContent{cert: cert}, nil
+	}
+
+	if lastErr == nil {
+		lastErr = errors.New("no tlog entries available to recover a certificate from")
+	}
+	return nil, fmt.Errorf("unable to recover signing certificate from transparency log: %w", lastErr)
+}
+
+// verifyCertificateAgainstFulcio checks cert chains to one of trustedRoot's
+// Fulcio certificate authorities and was valid at observerTime.
+func verifyCertificateAgainstFulcio(cert *x509.Certificate, trustedRoot root.TrustedRoot, observerTime time.Time) error {
+	cas := trustedRoot.FulcioCertificateAuthorities()
+	if len(cas) == 0 {
+		return errors.New("trusted root has no Fulcio certificate authorities configured")
+	}
+
+	var lastErr error
+	for _, ca := range cas {
+		roots := x509.NewCertPool()
+		roots.AddCert(ca.Root)
+
+		intermediates := x509.NewCertPool()
+		for _, intermediate := range ca.Intermediates {
+			intermediates.AddCert(intermediate)
+		}
+
+		_, err := cert.Verify(x509.VerifyOptions{
+			Roots:         roots,
+			Intermediates: intermediates,
+			CurrentTime:   observerTime,
+			KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		})
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+	}
+
+	return fmt.Errorf("certificate recovered from transparency log does not chain to a trusted Fulcio root: %w", lastErr)
+}
+
+// shardMatch pairs a LogEntryAnon returned by a shard search with its UUID,
+// so callers can tell genuinely distinct entries apart from the same entry
+// turning up more than once.
+type shardMatch struct {
+	uuid  string
+	entry rekorModels.LogEntryAnon
+}
+
+// searchShardForEntry searches a single Rekor shard for proposedEntry and
+// verifies every match's signed entry timestamp. It returns an error only
+// for failures local to this shard (unreachable client, failed search,
+// invalid SET); callers search remaining shards on error rather than
+// failing outright.
+func searchShardForEntry(tlogVerifier *root.TlogVerifier, proposedEntry rekorModels.ProposedEntry) ([]shardMatch, error) {
+	client, verifier, err := getRekorClient(tlogVerifier.BaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	searchParams := rekorEntries.NewSearchLogQueryParams()
+	searchLogQuery := rekorModels.SearchLogQuery{}
+	searchLogQuery.Entries = []rekorModels.ProposedEntry{proposedEntry}
+	searchParams.SetEntry(&searchLogQuery)
+
+	resp, err := client.Entries.SearchLogQuery(searchParams)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []shardMatch
+	for _, logEntry := range resp.Payload {
+		for uuid, v := range logEntry {
+			v := v
+			if err := rekorVerify.VerifyLogEntry(context.TODO(), &v, *verifier); err != nil {
+				return nil, fmt.Errorf("verifying log entry %s: %w", uuid, err)
+			}
+			matches = append(matches, shardMatch{uuid: uuid, entry: v})
+		}
+	}
+
+	return matches, nil
+}
+
+// resolveEarliestMatch picks the earliest-integrated entry across matches,
+// which may have come from more than one shard. It errors rather than
+// silently disambiguating when matches don't all agree on the entry's UUID,
+// since that means the content-hash search found genuinely distinct
+// entries and picking one over the other would be a guess.
+func resolveEarliestMatch(matches []shardMatch) (rekorModels.LogEntryAnon, error) {
+	distinctUUIDs := make(map[string]struct{}, len(matches))
+	for _, match := range matches {
+		distinctUUIDs[match.uuid] = struct{}{}
+	}
+	if len(distinctUUIDs) > 1 {
+		return rekorModels.LogEntryAnon{}, fmt.Errorf("ambiguous tlog entry: %d distinct entries matched across shards", len(distinctUUIDs))
+	}
+
+	earliest := matches[0].entry
+	for _, match := range matches[1:] {
+		if *match.entry.IntegratedTime < *earliest.IntegratedTime {
+			earliest = match.entry
+		}
+	}
+
+	return earliest, nil
+}
+
+// proposedEntryFromRekorEntry reconstructs the canonical hashedrekord/intoto/
+// rekord body carried by entry into a rekorModels.ProposedEntry, so it can be
+// used to search a shard by content rather than by log index.
+func proposedEntryFromRekorEntry(entry tlog.Entry) (rekorModels.ProposedEntry, error) {
+	body, err := base64.StdEncoding.DecodeString(entry.Body())
+	if err != nil {
+		return nil, fmt.Errorf("decoding tlog entry body: %w", err)
+	}
+
+	proposedEntry, err := rekorModels.UnmarshalProposedEntry(bytes.NewReader(body), runtime.JSONConsumer())
+	if err != nil {
+		return nil, fmt.Errorf("unmarshalling tlog entry body: %w", err)
+	}
+
+	return proposedEntry, nil
 }
 
 func getRekorClient(baseURL string) (*rekorGeneratedClient.Rekor, *signature.Verifier, error) {
@@ -0,0 +1,101 @@
+package verifier
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func generateSelfSignedCert(t *testing.T, notBefore, notAfter time.Time) (*x509.Certificate, []byte) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test"},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("creating certificate: %v", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parsing certificate: %v", err)
+	}
+
+	return cert, der
+}
+
+func TestRecoveredVerificationContentCompareKey(t *testing.T) {
+	cert, der := generateSelfSignedCert(t, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	other, otherDER := generateSelfSignedCert(t, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+
+	content := &recoveredVerificationContent{cert: cert}
+
+	if !content.CompareKey(der) {
+		t.Error("CompareKey() = false for the certificate's own DER bytes, want true")
+	}
+	if content.CompareKey(otherDER) {
+		t.Error("CompareKey() = true for an unrelated certificate, want false")
+	}
+
+	pubKeyDER, err := x509.MarshalPKIXPublicKey(cert.PublicKey)
+	if err != nil {
+		t.Fatalf("marshalling public key: %v", err)
+	}
+	if !content.CompareKey(pubKeyDER) {
+		t.Error("CompareKey() = false for the certificate's own bare public key, want true")
+	}
+
+	_ = other
+}
+
+// TestDerFromPEMOrRawCompareKey guards the PEM/DER mismatch between
+// EntryBody.PublicKeyOrCertificate (always PEM, per the Rekor schemas) and
+// recoveredVerificationContent.CompareKey (DER only): a tlog entry body's
+// key must still compare equal after being recovered from the log.
+func TestDerFromPEMOrRawCompareKey(t *testing.T) {
+	cert, der := generateSelfSignedCert(t, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	pemCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+
+	content := &recoveredVerificationContent{cert: cert}
+
+	if !content.CompareKey(derFromPEMOrRaw(pemCert)) {
+		t.Error("CompareKey(derFromPEMOrRaw(pemCert)) = false, want true")
+	}
+	if !content.CompareKey(derFromPEMOrRaw(der)) {
+		t.Error("CompareKey(derFromPEMOrRaw(der)) = false for already-DER input, want true")
+	}
+}
+
+func TestRecoveredVerificationContentValidAtTime(t *testing.T) {
+	notBefore := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	notAfter := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	cert, _ := generateSelfSignedCert(t, notBefore, notAfter)
+
+	content := &recoveredVerificationContent{cert: cert}
+
+	if !content.ValidAtTime(notBefore.Add(time.Hour)) {
+		t.Error("ValidAtTime() = false for a time within validity, want true")
+	}
+	if content.ValidAtTime(notBefore.Add(-time.Hour)) {
+		t.Error("ValidAtTime() = true for a time before NotBefore, want false")
+	}
+	if content.ValidAtTime(notAfter.Add(time.Hour)) {
+		t.Error("ValidAtTime() = true for a time after NotAfter, want false")
+	}
+}
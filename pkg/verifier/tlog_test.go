@@ -0,0 +1,51 @@
+package verifier
+
+import (
+	"testing"
+
+	rekorModels "github.com/sigstore/rekor/pkg/generated/models"
+)
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
+
+func TestResolveEarliestMatch(t *testing.T) {
+	t.Run("single match", func(t *testing.T) {
+		matches := []shardMatch{
+			{uuid: "uuid-1", entry: rekorModels.LogEntryAnon{IntegratedTime: int64Ptr(100)}},
+		}
+		got, err := resolveEarliestMatch(matches)
+		if err != nil {
+			t.Fatalf("resolveEarliestMatch() error = %v", err)
+		}
+		if *got.IntegratedTime != 100 {
+			t.Fatalf("resolveEarliestMatch() IntegratedTime = %d, want 100", *got.IntegratedTime)
+		}
+	})
+
+	t.Run("same entry from multiple shards picks earliest", func(t *testing.T) {
+		matches := []shardMatch{
+			{uuid: "uuid-1", entry: rekorModels.LogEntryAnon{IntegratedTime: int64Ptr(200)}},
+			{uuid: "uuid-1", entry: rekorModels.LogEntryAnon{IntegratedTime: int64Ptr(100)}},
+			{uuid: "uuid-1", entry: rekorModels.LogEntryAnon{IntegratedTime: int64Ptr(300)}},
+		}
+		got, err := resolveEarliestMatch(matches)
+		if err != nil {
+			t.Fatalf("resolveEarliestMatch() error = %v", err)
+		}
+		if *got.IntegratedTime != 100 {
+			t.Fatalf("resolveEarliestMatch() IntegratedTime = %d, want 100", *got.IntegratedTime)
+		}
+	})
+
+	t.Run("distinct entries across shards is an error", func(t *testing.T) {
+		matches := []shardMatch{
+			{uuid: "uuid-1", entry: rekorModels.LogEntryAnon{IntegratedTime: int64Ptr(100)}},
+			{uuid: "uuid-2", entry: rekorModels.LogEntryAnon{IntegratedTime: int64Ptr(200)}},
+		}
+		if _, err := resolveEarliestMatch(matches); err == nil {
+			t.Fatal("resolveEarliestMatch() error = nil, want error for ambiguous entries")
+		}
+	})
+}
@@ -0,0 +1,187 @@
+package verifier
+
+import (
+	"bytes"
+	"crypto/x509"
+	"errors"
+	"fmt"
+
+	"github.com/digitorus/pkcs7"
+	"github.com/digitorus/timestamp"
+
+	"github.com/github/sigstore-verifier/pkg/root"
+)
+
+// TimestampAuthorityVerifier verifies RFC3161 timestamp tokens as an
+// alternative signature-time source to a transparency log. It is a sibling
+// of ArtifactTransparencyLogVerifier: where that verifier trusts Rekor's
+// signed entry timestamp (or inclusion proof), this one trusts a TSA's
+// signed TimeStampToken, letting users who signed with a timestamp server
+// instead of (or in addition to) Rekor verify without a tlog round-trip.
+type TimestampAuthorityVerifier struct {
+	trustedRoot root.TrustedRoot
+	threshold   int
+}
+
+func NewTimestampAuthorityVerifier(trustedRoot root.TrustedRoot, threshold int) *TimestampAuthorityVerifier {
+	return &TimestampAuthorityVerifier{
+		trustedRoot: trustedRoot,
+		threshold:   threshold,
+	}
+}
+
+func (p *TimestampAuthorityVerifier) Verify(entity SignedEntity, opts VerifyOptions) error {
+	tokens, err := entity.TimestampAuthorityData()
+	if err != nil {
+		return err
+	}
+	if len(tokens) < p.threshold {
+		return fmt.Errorf("not enough timestamp authority tokens: %d < %d", len(tokens), p.threshold)
+	}
+
+	sigContent, err := entity.SignatureContent()
+	if err != nil {
+		return err
+	}
+	entitySignature := sigContent.GetSignature()
+
+	verificationContent, err := entity.VerificationContent()
+	if err != nil {
+		return err
+	}
+
+	for _, tokenBytes := range tokens {
+		token, err := timestamp.ParseResponse(tokenBytes)
+		if err != nil {
+			// Some TSAs hand back a bare TimeStampToken rather than a full
+			// TimeStampResp; fall back to parsing it directly.
+			token, err = timestamp.Parse(tokenBytes)
+			if err != nil {
+				return fmt.Errorf("parsing RFC3161 timestamp token: %w", err)
+			}
+		}
+
+		if err := verifyTokenSignature(token.RawToken); err != nil {
+			return err
+		}
+
+		if err := verifyMessageImprint(token, entitySignature); err != nil {
+			return err
+		}
+
+		if err := verifyTimestampCertChain(token, p.trustedRoot); err != nil {
+			return err
+		}
+
+		if !verificationContent.ValidAtTime(token.Time) {
+			return errors.New("timestamp authority time outside certificate validity")
+		}
+	}
+
+	return nil
+}
+
+// verifyTokenSignature checks the RFC3161 token's CMS/PKCS7 SignedData
+// signature against its own embedded certificate. timestamp.ParseResponse
+// and timestamp.Parse only decode the token's ASN.1 structure; on their own
+// they prove nothing about who produced it. This is what actually proves the
+// holder of the embedded certificate's private key signed this token,
+// before verifyTimestampCertChain is trusted to say anything about who that
+// certificate belongs to.
+func verifyTokenSignature(tokenBytes []byte) error {
+	p7, err := pkcs7.Parse(tokenBytes)
+	if err != nil {
+		return fmt.Errorf("parsing timestamp token as PKCS7: %w", err)
+	}
+
+	if err := p7.Verify(); err != nil {
+		return fmt.Errorf("timestamp token signature is invalid: %w", err)
+	}
+
+	return nil
+}
+
+// verifyMessageImprint checks that token's messageImprint covers the
+// artifact signature bytes, binding the timestamp to this specific
+// signature rather than some other blob the TSA happened to stamp.
+func verifyMessageImprint(token *timestamp.Timestamp, signature []byte) error {
+	hash := token.HashAlgorithm.New()
+	hash.Write(signature)
+	if !bytes.Equal(hash.Sum(nil), token.HashedMessage) {
+		return errors.New("timestamp messageImprint does not match bundle signature")
+	}
+	return nil
+}
+
+// verifyTimestampCertChain verifies token's signing certificate chains to
+// one of trustedRoot's configured timestamp authorities and was valid at
+// the token's own genTime.
+func verifyTimestampCertChain(token *timestamp.Timestamp, trustedRoot root.TrustedRoot) error {
+	p7, err := pkcs7.Parse(token.RawToken)
+	if err != nil {
+		return fmt.Errorf("parsing timestamp token as PKCS7: %w", err)
+	}
+	signer := p7.GetOnlySigner()
+	if signer == nil {
+		return errors.New("timestamp token carries no signing certificate")
+	}
+
+	authorities := trustedRoot.TimestampAuthorities()
+	if len(authorities) == 0 {
+		return errors.New("trusted root has no timestamp authorities configured")
+	}
+
+	var lastErr error
+	for _, authority := range authorities {
+		roots := x509.NewCertPool()
+		roots.AddCert(authority.Root)
+
+		intermediates := x509.NewCertPool()
+		for _, intermediate := range authority.Intermediates {
+			intermediates.AddCert(intermediate)
+		}
+
+		_, err := signer.Verify(x509.VerifyOptions{
+			Roots:         roots,
+			Intermediates: intermediates,
+			CurrentTime:   token.Time,
+			KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageTimeStamping},
+		})
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+	}
+
+	return fmt.Errorf("timestamp token signing certificate does not chain to a trusted timestamp authority: %w", lastErr)
+}
+
+// TimeVerifier is satisfied by both ArtifactTransparencyLogVerifier and
+// TimestampAuthorityVerifier, letting policy require N-of-M independent
+// signature-time sources to agree rather than trusting a single one.
+type TimeVerifier interface {
+	Verify(entity SignedEntity, opts VerifyOptions) error
+}
+
+// VerifyTimeThreshold runs entity against every verifier and requires at
+// least threshold of them to succeed, so a user signing with both Rekor and
+// an RFC3161 timestamp server can require, say, 1-of-2 or 2-of-2 agreement
+// instead of depending on a single time source.
+func VerifyTimeThreshold(entity SignedEntity, opts VerifyOptions, threshold int, verifiers ...TimeVerifier) error {
+	var errs []error
+	successes := 0
+
+	for _, v := range verifiers {
+		if err := v.Verify(entity, opts); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		successes++
+	}
+
+	if successes < threshold {
+		return fmt.Errorf("only %d/%d signature-time verifiers succeeded, %d required: %w", successes, len(verifiers), threshold, errors.Join(errs...))
+	}
+
+	return nil
+}
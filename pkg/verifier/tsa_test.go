@@ -0,0 +1,119 @@
+package verifier
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/digitorus/pkcs7"
+	"github.com/digitorus/timestamp"
+)
+
+// signTokenContent builds a PKCS7 SignedData envelope over content, signed
+// by a freshly generated self-signed certificate, standing in for a TSA's
+// TimeStampToken for tests that only care about the CMS signature.
+func signTokenContent(t *testing.T, content []byte) []byte {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test tsa"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("creating certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parsing certificate: %v", err)
+	}
+
+	sd, err := pkcs7.NewSignedData(content)
+	if err != nil {
+		t.Fatalf("creating signed data: %v", err)
+	}
+	if err := sd.AddSigner(cert, key, pkcs7.SignerInfoConfig{}); err != nil {
+		t.Fatalf("adding signer: %v", err)
+	}
+
+	signed, err := sd.Finish()
+	if err != nil {
+		t.Fatalf("finishing signed data: %v", err)
+	}
+	return signed
+}
+
+func TestVerifyTokenSignature(t *testing.T) {
+	tokenBytes := signTokenContent(t, []byte("timestamp token content"))
+
+	if err := verifyTokenSignature(tokenBytes); err != nil {
+		t.Fatalf("verifyTokenSignature() error = %v, want nil for an untampered token", err)
+	}
+
+	tampered := make([]byte, len(tokenBytes))
+	copy(tampered, tokenBytes)
+	tampered[len(tampered)-1] ^= 0xFF
+
+	if err := verifyTokenSignature(tampered); err == nil {
+		t.Fatal("verifyTokenSignature() error = nil, want error for a token with a tampered signature")
+	}
+}
+
+func TestVerifyMessageImprint(t *testing.T) {
+	signature := []byte("bundle signature bytes")
+
+	hash := crypto.SHA256.New()
+	hash.Write(signature)
+	digest := hash.Sum(nil)
+
+	t.Run("matching imprint", func(t *testing.T) {
+		token := &timestamp.Timestamp{
+			HashAlgorithm: crypto.SHA256,
+			HashedMessage: digest,
+		}
+		if err := verifyMessageImprint(token, signature); err != nil {
+			t.Fatalf("verifyMessageImprint() error = %v, want nil", err)
+		}
+	})
+
+	t.Run("mismatched imprint", func(t *testing.T) {
+		token := &timestamp.Timestamp{
+			HashAlgorithm: crypto.SHA256,
+			HashedMessage: []byte("not the right digest"),
+		}
+		if err := verifyMessageImprint(token, signature); err == nil {
+			t.Fatal("verifyMessageImprint() error = nil, want error for mismatched imprint")
+		}
+	})
+
+	t.Run("imprint over different signature", func(t *testing.T) {
+		token := &timestamp.Timestamp{
+			HashAlgorithm: crypto.SHA256,
+			HashedMessage: digest,
+		}
+		if err := verifyMessageImprint(token, []byte("a different signature")); err == nil {
+			t.Fatal("verifyMessageImprint() error = nil, want error when signature bytes differ")
+		}
+	})
+}
+
+// TimeVerifier conformance is load-bearing for VerifyTimeThreshold's N-of-M
+// policy: if ArtifactTransparencyLogVerifier's Verify signature drifts from
+// TimestampAuthorityVerifier's, this stops compiling.
+var (
+	_ TimeVerifier = (*ArtifactTransparencyLogVerifier)(nil)
+	_ TimeVerifier = (*TimestampAuthorityVerifier)(nil)
+)